@@ -0,0 +1,113 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func zeroAllocRouter() *Router {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+	rtr.Handle("GET", "/widgets/*", makeRoute(2))
+	rtr.Handle("GET", "/posts/{id:[0-9]+}", makeRoute(3))
+
+	return rtr
+}
+
+func benchRequest(path string) *http.Request {
+	u, er := url.Parse(path)
+	if er != nil {
+		panic(er)
+	}
+
+	return &http.Request{Method: "GET", URL: u}
+}
+
+// TestZeroAllocDispatch hard-asserts that, once a Router has warmed up its
+// args pool (see Router.getArgs), dispatching a request against a literal,
+// untyped-wildcard, or regex route doesn't touch the heap. Typed routes
+// aren't included here: reflect.Value.Call allocates internally regardless
+// of how its argument slice was obtained, so that path is covered by
+// BenchmarkServeHTTPTyped below instead of a zero-alloc assertion.
+func TestZeroAllocDispatch(t *testing.T) {
+	rtr := zeroAllocRouter()
+	w := httptest.NewRecorder()
+
+	cases := []struct {
+		name string
+		path string
+	}{
+		{"literal", "/widgets"},
+		{"wildcard", "/widgets/anything"},
+		{"regex", "/posts/42"},
+	}
+
+	for _, c := range cases {
+		req := benchRequest(c.path)
+
+		// Warm up the pools: the first call or two may still grow the
+		// pooled args slice to its steady-state capacity.
+		for i := 0; i < 2; i++ {
+			rtr.ServeHTTP(w, req)
+		}
+
+		allocs := testing.AllocsPerRun(1000, func() {
+			rtr.ServeHTTP(w, req)
+		})
+
+		if allocs != 0 {
+			t.Errorf("%s: expected 0 allocs/op, got %v", c.name, allocs)
+		}
+	}
+}
+
+func BenchmarkServeHTTPLiteral(b *testing.B) {
+	rtr := zeroAllocRouter()
+	w := httptest.NewRecorder()
+	req := benchRequest("/widgets")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rtr.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkServeHTTPWildcard(b *testing.B) {
+	rtr := zeroAllocRouter()
+	w := httptest.NewRecorder()
+	req := benchRequest("/widgets/anything")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rtr.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkServeHTTPRegex(b *testing.B) {
+	rtr := zeroAllocRouter()
+	w := httptest.NewRecorder()
+	req := benchRequest("/posts/42")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rtr.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkServeHTTPTyped(b *testing.B) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/users/:id:int", func(w http.ResponseWriter, r *http.Request, id int64) error {
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	req := benchRequest("/users/42")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rtr.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func serveTyped(rtr *Router, method, urlStr string) *httptest.ResponseRecorder {
+	u, er := url.Parse(urlStr)
+	if er != nil {
+		panic(er)
+	}
+
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: method, URL: u})
+
+	return w
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+	rtr.Handle("POST", "/widgets", makeRoute(2))
+
+	w := serveTyped(rtr, "DELETE", "/widgets")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("expected Allow: GET, POST, got %q", allow)
+	}
+}
+
+func TestMethodNotAllowedCanBeDisabled(t *testing.T) {
+	rtr := NewRouter()
+	rtr.HandleMethodNotAllowed = false
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+
+	w := serveTyped(rtr, "DELETE", "/widgets")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected a plain 404 with HandleMethodNotAllowed off, got %d", w.Code)
+	}
+}
+
+func TestAutomaticOPTIONS(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+	rtr.Handle("POST", "/widgets", makeRoute(2))
+
+	w := serveTyped(rtr, "OPTIONS", "/widgets")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("expected Allow: GET, POST, got %q", allow)
+	}
+}
+
+func TestExplicitOPTIONSRouteWins(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+	rtr.Handle("OPTIONS", "/widgets", makeRoute(2))
+
+	lastVal = 0
+	serveTyped(rtr, "OPTIONS", "/widgets")
+	if lastVal != 2 {
+		t.Errorf("expected the explicit OPTIONS route to be invoked, got lastVal=%d", lastVal)
+	}
+}
+
+func TestAutomaticOPTIONSCanBeDisabled(t *testing.T) {
+	rtr := NewRouter()
+	rtr.HandleOPTIONS = false
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+
+	w := serveTyped(rtr, "OPTIONS", "/widgets")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected a plain 404 with HandleOPTIONS off, got %d", w.Code)
+	}
+}
+
+func TestUnknownPathStillReturns404(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/widgets", makeRoute(1))
+
+	w := serveTyped(rtr, "DELETE", "/gadgets")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a wholly unknown path, got %d", w.Code)
+	}
+}
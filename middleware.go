@@ -0,0 +1,69 @@
+package router
+
+// Middleware wraps a Route to add cross-cutting behavior -- auth, logging,
+// CORS, recovery, and the like -- without modifying the route itself. See
+// Router.Use.
+type Middleware func(Route) Route
+
+// Use registers middleware that wraps every route resolved within the
+// (method, url) prefix, including url itself and any deeper prefix or route
+// registered under it. Middleware registered at a shallower prefix wraps
+// outer to middleware (and routes) registered at a deeper one, e.g.
+//
+//     rtr.Use("GET", "/api/1/", mwA)
+//     rtr.Use("GET", "/api/1/admin/", mwB)
+//
+// results in mwA(mwB(route)) for any route under /api/1/admin/, but just
+// mwA(route) elsewhere under /api/1/.
+//
+// Multiple middlewares passed in one call apply in the order given, with
+// the first wrapping the rest -- Use(m, u, a, b) behaves like Use(m, u, a)
+// followed by Use(m, u, b) at the same prefix.
+func (r *Router) Use(method, url string, mw ...Middleware) {
+	sr := r.insertSubrouter(method, url)
+	sr.middleware = append(sr.middleware, mw...)
+}
+
+// RouteGroup is a builder scoped to a URL prefix, returned by Router.Group.
+// Its methods mirror Router's, joining the given url onto the group's
+// prefix, so routes and middleware can be registered for a subtree without
+// repeating the prefix at every call.
+type RouteGroup struct {
+	router *Router
+	prefix string
+}
+
+// Group returns a RouteGroup whose Handle, SetDefault, SetErrorHandler, and
+// Use methods register against prefix+url instead of url alone. This
+// mirrors the grouping model of other routers (e.g. gorilla/mux's Subrouter
+// or chi's Route), implemented here as a thin wrapper since the underlying
+// trie already scopes defaults, error handlers, and middleware to a prefix.
+func (r *Router) Group(prefix string) *RouteGroup {
+	return &RouteGroup{router: r, prefix: prefix}
+}
+
+// Group returns a RouteGroup scoped to g's prefix joined with prefix,
+// letting groups nest.
+func (g *RouteGroup) Group(prefix string) *RouteGroup {
+	return &RouteGroup{router: g.router, prefix: g.prefix + prefix}
+}
+
+// Handle is equivalent to Router.Handle with url joined onto the group's prefix.
+func (g *RouteGroup) Handle(method, url string, handler interface{}) {
+	g.router.Handle(method, g.prefix+url, handler)
+}
+
+// SetDefault is equivalent to Router.SetDefault with url joined onto the group's prefix.
+func (g *RouteGroup) SetDefault(method, url string, rt Route) {
+	g.router.SetDefault(method, g.prefix+url, rt)
+}
+
+// SetErrorHandler is equivalent to Router.SetErrorHandler with url joined onto the group's prefix.
+func (g *RouteGroup) SetErrorHandler(method, url string, rt ErrorHandler) {
+	g.router.SetErrorHandler(method, g.prefix+url, rt)
+}
+
+// Use is equivalent to Router.Use with url joined onto the group's prefix.
+func (g *RouteGroup) Use(method, url string, mw ...Middleware) {
+	g.router.Use(method, g.prefix+url, mw...)
+}
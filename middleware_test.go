@@ -0,0 +1,60 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+)
+
+func makeTraceMiddleware(trace *[]string, name string) Middleware {
+	return func(next Route) Route {
+		return func(w http.ResponseWriter, r *http.Request, args []string) error {
+			*trace = append(*trace, name)
+			return next(w, r, args)
+		}
+	}
+}
+
+func TestMiddlewareOrderingAndScope(t *testing.T) {
+	var trace []string
+
+	rtr := NewRouter()
+	rtr.Handle("GET", "/", makeRoute(1))
+	rtr.Handle("GET", "/api/1/post", makeRoute(2))
+	rtr.Handle("GET", "/api/1/admin/ban", makeRoute(3))
+
+	rtr.Use("GET", "/api/1/", makeTraceMiddleware(&trace, "outer"))
+	rtr.Use("GET", "/api/1/admin/", makeTraceMiddleware(&trace, "inner"))
+
+	testRoute(rtr, "GET", "/")
+	if len(trace) != 0 {
+		t.Errorf("expected no middleware for /, got %v", trace)
+	}
+
+	testRoute(rtr, "GET", "/api/1/post")
+	if got := trace; len(got) != 1 || got[0] != "outer" {
+		t.Errorf("expected [outer] for /api/1/post, got %v", got)
+	}
+
+	trace = nil
+	testRoute(rtr, "GET", "/api/1/admin/ban")
+	if got := trace; len(got) != 2 || got[0] != "outer" || got[1] != "inner" {
+		t.Errorf("expected [outer inner] for /api/1/admin/ban, got %v", got)
+	}
+}
+
+func TestRouteGroup(t *testing.T) {
+	var trace []string
+
+	rtr := NewRouter()
+	api := rtr.Group("/api/1")
+	api.Use("GET", "/", makeTraceMiddleware(&trace, "api"))
+	api.Handle("GET", "/post", makeRoute(1))
+
+	testRoute(rtr, "GET", "/api/1/post")
+	if lastVal != 1 {
+		t.Errorf("expected route registered through Group to match, got %d", lastVal)
+	}
+	if len(trace) != 1 || trace[0] != "api" {
+		t.Errorf("expected middleware registered through Group to apply, got %v", trace)
+	}
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// cleanPath normalizes p the way Router.CleanPath promises: collapsing "//"
+// and resolving "." and ".." segments, while preserving a trailing slash
+// (path.Clean alone would strip it). path.Clean already returns p itself,
+// unmodified, when it's already clean, so this has no allocation on the
+// common case of an already-canonical path.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	trailingSlash := len(p) > 1 && strings.HasSuffix(p, "/")
+
+	cp := path.Clean(p)
+	if trailingSlash && !strings.HasSuffix(cp, "/") {
+		if p[:len(p)-1] == cp {
+			return p
+		}
+		cp += "/"
+	}
+
+	return cp
+}
+
+// isRedirectableMethod reports whether method is safe to issue a canonical-
+// path redirect for without losing a request body -- GET and HEAD.
+func isRedirectableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// redirect sends req to path using r.RedirectCode, preserving the
+// request's query string.
+func (r *Router) redirect(w http.ResponseWriter, req *http.Request, path string) {
+	u := *req.URL
+	u.Path = path
+	http.Redirect(w, req, u.String(), r.RedirectCode)
+}
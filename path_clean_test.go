@@ -0,0 +1,92 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCleanPathRedirectsGET(t *testing.T) {
+	rtr := NewRouter()
+	rtr.CleanPath = true
+	rtr.Handle("GET", "/foo/bar", makeRoute(1))
+
+	u, _ := url.Parse("/foo//./baz/../bar")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected a 301 redirect, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/foo/bar" {
+		t.Errorf("expected redirect to /foo/bar, got %q", loc)
+	}
+}
+
+func TestCleanPathRoutesNonGETWithoutRedirect(t *testing.T) {
+	rtr := NewRouter()
+	rtr.CleanPath = true
+	rtr.Handle("POST", "/foo/bar", makeRoute(1))
+
+	lastVal = 0
+	u, _ := url.Parse("/foo//bar")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "POST", URL: u})
+
+	if w.Code == http.StatusMovedPermanently {
+		t.Fatal("did not expect a redirect for a POST request")
+	}
+	if lastVal != 1 {
+		t.Errorf("expected the route to be invoked against the cleaned path, got lastVal=%d", lastVal)
+	}
+}
+
+func TestCleanPathDisabledByDefault(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/foo/bar", makeRoute(1))
+
+	u, _ := url.Parse("/foo//bar")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+
+	if w.Code == http.StatusMovedPermanently {
+		t.Fatal("did not expect CleanPath behavior when disabled")
+	}
+}
+
+// This router already matches "/foo" and "/foo/" identically for ordinary
+// routes (see the package notes) -- there's no RedirectTrailingSlash
+// feature here, since there's no "only one of the two is registered" case
+// for it to act on.
+func TestOrdinaryRouteMatchesWithOrWithoutTrailingSlash(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/foo/", makeRoute(1))
+
+	lastVal = 0
+	u, _ := url.Parse("/foo")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+
+	if w.Code == http.StatusMovedPermanently {
+		t.Fatal("did not expect a redirect; /foo already matches /foo/ for ordinary routes")
+	}
+	if lastVal != 1 {
+		t.Errorf("expected the registered route to be invoked, got lastVal=%d", lastVal)
+	}
+}
+
+func TestRedirectCodeConfigurable(t *testing.T) {
+	rtr := NewRouter()
+	rtr.CleanPath = true
+	rtr.RedirectCode = http.StatusPermanentRedirect
+	rtr.Handle("GET", "/foo/bar", makeRoute(1))
+
+	u, _ := url.Parse("/foo//bar")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected a 308 redirect, got %d", w.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regexChild is a trie edge for a regex-constrained path segment, e.g. the
+// "{id:[0-9]+}" segment of "/posts/{id:[0-9]+}". Regex children are tried,
+// in registration order, after a literal-child lookup misses and before any
+// typed or untyped wildcard.
+type regexChild struct {
+	name    string
+	pattern string
+	re      *regexp.Regexp
+	node    *subrouter
+}
+
+// parseRegexSegment reports whether part is a regex-constrained segment of
+// the form "{name:pattern}", returning its param name and regex pattern if
+// so. pattern may itself contain ':' (e.g. in a character class or a
+// non-capturing group), since only the first ':' after the opening brace is
+// treated as the name/pattern separator.
+func parseRegexSegment(part string) (name, pattern string, ok bool) {
+	if len(part) < 2 || part[0] != '{' || part[len(part)-1] != '}' {
+		return "", "", false
+	}
+
+	inner := part[1 : len(part)-1]
+
+	idx := strings.IndexByte(inner, ':')
+	if idx < 0 {
+		return "", "", false
+	}
+
+	return inner[:idx], inner[idx+1:], true
+}
+
+// insertRegexChild finds or creates the child node for the named regex
+// segment, compiling pattern (anchored to match the whole segment) once at
+// registration time.
+func (sr *subrouter) insertRegexChild(name, pattern string) *subrouter {
+	for _, rc := range sr.regexChildren {
+		if rc.name == name && rc.pattern == pattern {
+			return rc.node
+		}
+	}
+
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+
+	child := newSubrouter()
+	child.paramTypeNames = sr.paramTypeNames
+	child.capturingParams = sr.capturingParams + 1
+
+	sr.regexChildren = append(sr.regexChildren, &regexChild{
+		name:    name,
+		pattern: pattern,
+		re:      re,
+		node:    child,
+	})
+
+	return child
+}
+
+// matchRegexChild returns the first regex child (in registration order)
+// whose pattern fully matches pathPart, or nil if none match.
+func (sr *subrouter) matchRegexChild(pathPart string) *subrouter {
+	for _, rc := range sr.regexChildren {
+		if rc.re.MatchString(pathPart) {
+			return rc.node
+		}
+	}
+
+	return nil
+}
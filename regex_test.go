@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRegexRoutes(t *testing.T) {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/posts/{id:[0-9]+}", makeRoute(1))
+	rtr.Handle("GET", "/files/{name:[a-z][a-z0-9_-]*}", makeRoute(2))
+
+	testRoute(rtr, "GET", "/posts/42")
+	if lastVal != 1 {
+		t.Errorf("expected numeric id route, got %d", lastVal)
+	}
+	if len(lastArgs) != 1 || lastArgs[0] != "42" {
+		t.Errorf("expected args [42], got %#v", lastArgs)
+	}
+
+	testRoute(rtr, "GET", "/files/report_v2")
+	if lastVal != 2 {
+		t.Errorf("expected filename route, got %d", lastVal)
+	}
+	if len(lastArgs) != 1 || lastArgs[0] != "report_v2" {
+		t.Errorf("expected args [report_v2], got %#v", lastArgs)
+	}
+}
+
+func TestRegexAmbiguityResolution(t *testing.T) {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/posts/latest", makeRoute(1))      // literal
+	rtr.Handle("GET", "/posts/{id:[0-9]+}", makeRoute(2)) // regex
+	rtr.Handle("GET", "/posts/*", makeRoute(3))           // wildcard
+
+	testRoute(rtr, "GET", "/posts/latest")
+	if lastVal != 1 {
+		t.Errorf("expected literal to win over regex/wildcard, got %d", lastVal)
+	}
+
+	testRoute(rtr, "GET", "/posts/42")
+	if lastVal != 2 {
+		t.Errorf("expected regex to win over wildcard, got %d", lastVal)
+	}
+
+	testRoute(rtr, "GET", "/posts/not-numeric")
+	if lastVal != 3 {
+		t.Errorf("expected wildcard fallback when regex doesn't match, got %d", lastVal)
+	}
+}
+
+func TestRegexDoesNotMatchFallsThroughWithNoWildcard(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/posts/{id:[0-9]+}", makeRoute(1))
+
+	lastVal = -1
+
+	u, _ := url.Parse("/posts/abc")
+	w := httptest.NewRecorder()
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+
+	if lastVal != -1 {
+		t.Errorf("expected no match for a non-numeric id with no wildcard fallback, got %d", lastVal)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 from the built-in nullRoute, got %d", w.Code)
+	}
+}
@@ -35,14 +35,94 @@
 //     rtr.SetDefault("GET", "/", rt1)     // matches /foo
 //     rtr.SetDefault("GET", "/foo", rt2)  // matches /foo/
 //
-// With the above router, "/foo" hits rt1, while "/foo/" hits rt2. The logic 
-// (in MVC terms, at least) is that "/foo" corresponds to the "foo" method of the 
+// With the above router, "/foo" hits rt1, while "/foo/" hits rt2. The logic
+// (in MVC terms, at least) is that "/foo" corresponds to the "foo" method of the
 // root controller, whereas "/foo/" is the "index" method of the "foo" controller.
+//
+// Typed path parameters
+//
+// A path segment of the form ":name:type" declares a typed wildcard, e.g.
+// "/users/:id:int/posts/:slug:string". Handlers bound to a pattern with typed
+// wildcards may use a Go signature with matching argument types instead of
+// the usual Route signature:
+//
+//     rtr.Handle("GET", "/users/:id:int/posts/:slug:string",
+//         func(w http.ResponseWriter, r *http.Request, id int64, slug string) error {
+//             ...
+//         })
+//
+// The handler's signature is checked against the pattern with reflection at
+// registration time, so a mismatch panics immediately rather than misbehaving
+// per-request. Built-in types are "int", "uint", and "string"; see
+// RegisterParamType to add more (e.g. "uuid"). Untyped "*" wildcards and the
+// func(w, r, args []string) error Route form continue to work unchanged.
+//
+// Middleware
+//
+// Router.Use attaches Middleware to a (method, url) prefix; it wraps every
+// route resolved under that prefix, shallowest prefix outermost, without
+// having to wrap each handler individually:
+//
+//     rtr.Use("GET", "/api/1/", requireAuth)
+//     rtr.Handle("GET", "/api/1/post", api1Post) // wrapped by requireAuth
+//
+// Router.Group returns a RouteGroup for registering several routes and
+// middleware under a shared prefix without repeating it.
+//
+// Regex-constrained segments
+//
+// A path segment of the form "{name:pattern}" is compiled into a regular
+// expression, anchored to the whole segment, at registration time, e.g.
+// "/posts/{id:[0-9]+}" or "/files/{name:[a-z][a-z0-9_-]*}". The matched
+// value is added to args just like a "*" wildcard. When a segment could
+// match more than one of a literal, a regex, a typed wildcard, or "*",
+// precedence is literal > regex > typed wildcard > "*"; regex siblings are
+// tried in registration order.
+//
+// Method Not Allowed and OPTIONS
+//
+// By default (Router.HandleMethodNotAllowed), a request whose method has no
+// route for an otherwise-known path gets an automatic 405 with an Allow
+// header listing the methods that do. Similarly (Router.HandleOPTIONS), an
+// OPTIONS request on a known path gets an automatic 200 with the same Allow
+// header, unless an OPTIONS route was registered for that path explicitly.
+// Both default to true and can be set to false per Router to opt out.
+//
+// Path canonicalization
+//
+// Router.CleanPath, off by default, normalizes a request's path -- folding
+// "//" and resolving "." and ".." -- before routing it. A GET/HEAD request
+// whose path normalizes to something different is redirected there (via
+// Router.RedirectCode, 301 by default) when Router.CleanPathRedirect is
+// also true (the default); requests with other methods are routed against
+// the canonical path directly rather than redirected, since redirecting
+// would require the client to resend its body.
+//
+// There is deliberately no RedirectTrailingSlash: this router already
+// splits a path into non-empty segments only (see the /foo vs. /foo/ note
+// above, which is about default and error handlers, not routes), so an
+// ordinary route registered for either "/foo" or "/foo/" already matches
+// both forms, and adding or removing a trailing slash never changes which
+// node a path resolves to -- there is no "only one of the two is
+// registered" case for it to redirect.
+//
+// Dispatch allocation
+//
+// Steady-state dispatch -- once CleanPath has, if enabled, settled on the
+// path actually being routed -- avoids the heap where it reasonably can:
+// the path is walked segment-by-segment in place (see nextSegment) rather
+// than via strings.Split, the common HTTP methods are resolved through a
+// small array instead of the method map, and the wildcard args slice is
+// drawn from a pool sized to the deepest wildcard pattern registered on
+// the Router, across all methods.
 package router
 
 import (
 	"net/http"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Route is a type alias for a handler function. 
@@ -59,6 +139,12 @@ import (
 // If a non-nil error is returned, it will be passed to the nearest ErrorHandler.
 type Route func(w http.ResponseWriter, r *http.Request, args []string) error
 
+// routeType is Route's reflect.Type, used by Handle to recognize a handler
+// with Route's underlying signature even when its static type is an
+// unnamed func type (e.g. a bare closure or a plain top-level function)
+// rather than Route itself.
+var routeType = reflect.TypeOf(Route(nil))
+
 // ErrorHandler is a specialized route that is invoked when a Router returns an error.
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, er error)
 
@@ -77,25 +163,177 @@ func nullErrorHandler(w http.ResponseWriter, r *http.Request, er error) {
 // routing system.
 type Router struct {
 	children     map[string]*subrouter
+
+	// methodRoots caches children[method] for the common HTTP methods,
+	// indexed by methodID, so dispatch can skip the map lookup for them.
+	methodRoots [numMethodIDs]*subrouter
+
+	// maxArgs is the largest number of wildcard/typed/regex segments in any
+	// registered pattern so far. argsPool hands out slices with that much
+	// spare capacity so ordinary dispatch doesn't grow (and reallocate)
+	// the args slice per request.
+	maxArgs  int
+	argsPool sync.Pool
+
+	// HandleMethodNotAllowed, if true (the default), makes ServeHTTP respond
+	// with an HTTP 405 and an Allow header listing the other methods
+	// registered for the exact path, when the request's method has no
+	// route for that path but at least one other method does.
+	HandleMethodNotAllowed bool
+
+	// HandleOPTIONS, if true (the default), makes ServeHTTP synthesize an
+	// OPTIONS response -- HTTP 200 with an Allow header -- for any path
+	// known to some method, unless an OPTIONS route has been registered
+	// for that path explicitly.
+	HandleOPTIONS bool
+
+	// CleanPath, if true, normalizes a request's path -- collapsing "//",
+	// resolving "." and ".." -- before routing it. Off by default.
+	CleanPath bool
+
+	// CleanPathRedirect, when CleanPath is true, controls whether a GET or
+	// HEAD request whose path normalizes to something different is
+	// redirected to the canonical path (true, the default) rather than
+	// simply routed against the cleaned path in place. Requests with other
+	// methods are always routed against the cleaned path directly, since
+	// redirecting them would require the client to resend its body.
+	CleanPathRedirect bool
+
+	// RedirectCode is the HTTP status used for a CleanPathRedirect redirect.
+	// Defaults to http.StatusMovedPermanently (301); set it to
+	// http.StatusPermanentRedirect (308) to have clients preserve the
+	// request method and body across the redirect.
+	RedirectCode int
 }
 
 // NewRouter constructs a new Router.
 func NewRouter() *Router {
-	return &Router{
-		children:     make(map[string]*subrouter),
+	r := &Router{
+		children:               make(map[string]*subrouter),
+		HandleMethodNotAllowed: true,
+		HandleOPTIONS:          true,
+		CleanPathRedirect:      true,
+		RedirectCode:           http.StatusMovedPermanently,
+	}
+
+	r.argsPool.New = func() interface{} {
+		args := make([]string, 0, r.maxArgs)
+		return &args
+	}
+
+	return r
+}
+
+// methodID indexes Router.methodRoots for the common HTTP methods, so
+// dispatch against one of them can skip the children map lookup.
+type methodID int
+
+const (
+	methodGet methodID = iota
+	methodHead
+	methodPost
+	methodPut
+	methodPatch
+	methodDelete
+	methodOptions
+	numMethodIDs
+)
+
+// commonMethodID reports the methodID for method (an upper-cased HTTP verb
+// such as req.Method), if it's one of the common ones cached in
+// Router.methodRoots.
+func commonMethodID(method string) (methodID, bool) {
+	switch method {
+	case http.MethodGet:
+		return methodGet, true
+	case http.MethodHead:
+		return methodHead, true
+	case http.MethodPost:
+		return methodPost, true
+	case http.MethodPut:
+		return methodPut, true
+	case http.MethodPatch:
+		return methodPatch, true
+	case http.MethodDelete:
+		return methodDelete, true
+	case http.MethodOptions:
+		return methodOptions, true
+	default:
+		return 0, false
 	}
 }
 
+// rootFor returns the subrouter registered for method (an upper-cased HTTP
+// verb such as req.Method), preferring the methodRoots array for the common
+// methods over the children map.
+func (r *Router) rootFor(method string) (*subrouter, bool) {
+	if id, ok := commonMethodID(method); ok {
+		if sr := r.methodRoots[id]; sr != nil {
+			return sr, true
+		}
+		return nil, false
+	}
+
+	sr, ok := r.children[strings.ToLower(method)]
+	return sr, ok
+}
+
+// getArgs returns a *[]string, drawn from argsPool, whose slice has enough
+// spare capacity for the deepest wildcard pattern registered so far and a
+// length of zero. It's a pointer, rather than the slice itself, so that
+// putArgs can report the grown slice (if descendPath had to grow past its
+// capacity) back to the pool without boxing a slice header into the
+// interface{} sync.Pool takes on every call -- see putArgs.
+//
+// Callers must return it with putArgs once they're done with it.
+func (r *Router) getArgs() *[]string {
+	p := r.argsPool.Get().(*[]string)
+	*p = (*p)[:0]
+	return p
+}
+
+// putArgs returns an args pointer obtained from getArgs to the pool, first
+// storing args -- which may be a different, larger slice than the one *p
+// held on the way out, if dispatch had to grow it -- back through it.
+func (r *Router) putArgs(p *[]string, args []string) {
+	*p = args
+	r.argsPool.Put(p)
+}
+
 // Handle registers a new Route corresponding to the provided (method, url) pair.
 // If there is already a route registered for the pair, it panics.
-func (r *Router) Handle(method, url string, rt Route) {
+//
+// handler is normally a Route, but it may also be any func whose signature is
+// func(w http.ResponseWriter, r *http.Request, <typed args...>) error, where
+// <typed args...> match the typed wildcards declared in url (see the package
+// notes on typed path parameters). The signature is checked with reflection
+// at registration time, so a mismatched handler panics here rather than
+// misbehaving at request time.
+func (r *Router) Handle(method, url string, handler interface{}) {
 	sr := r.insertSubrouter(method, url)
 
 	if sr.route != nil {
 		panic("router: already exists a route for " + method + " " + url)
 	}
 
-	sr.route = rt
+	if rt, ok := handler.(Route); ok {
+		sr.route = rt
+		return
+	}
+
+	// Plain func(w http.ResponseWriter, r *http.Request, args []string) error
+	// handlers -- the overwhelmingly common case -- reach here too: a bare
+	// closure or a top-level function declared with that signature, rather
+	// than the named Route type, doesn't satisfy the type assertion above,
+	// since its static type is the unnamed func type instead. Check
+	// structurally and convert, rather than requiring callers to write
+	// Route(myFunc) everywhere.
+	if hv := reflect.ValueOf(handler); hv.Kind() == reflect.Func && hv.Type().ConvertibleTo(routeType) {
+		sr.route = hv.Convert(routeType).Interface().(Route)
+		return
+	}
+
+	sr.route = sr.bindTypedHandler(handler)
 }
 
 // SetDefault registers a default Route for all unmatched requests whose prefix
@@ -129,15 +367,77 @@ func (r *Router) SetErrorHandler(method, url string, rt ErrorHandler) {
 
 // ServeHTTP fetches the best matching route, invokes it, then calls the best-matching
 // error handler if the route returned an error.
+//
+// If CleanPath canonicalizes the request's path to something different,
+// ServeHTTP redirects a GET/HEAD request there while CleanPathRedirect is
+// true; requests with other methods, and GET/HEAD requests with
+// CleanPathRedirect off, are routed against the canonical path directly.
+//
+// If the request's method has no route registered for the exact path, but
+// the path is known to some other method, ServeHTTP handles it directly
+// instead of falling through to the usual default/404 behavior: an OPTIONS
+// request gets a synthesized 200 with an Allow header (HandleOPTIONS), and
+// any other method gets a 405 with an Allow header (HandleMethodNotAllowed).
+// Both are enabled by default and can be disabled per Router.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	pathParts := strings.Split(req.URL.Path, "/")
-	rt, erh, args := r.findRoute(req.Method, pathParts)
+	reqPath := req.URL.Path
+
+	if r.CleanPath {
+		if cleaned := cleanPath(reqPath); cleaned != reqPath {
+			if r.CleanPathRedirect && isRedirectableMethod(req.Method) {
+				r.redirect(w, req, cleaned)
+				return
+			}
+			reqPath = cleaned
+		}
+	}
+
+	argsP := r.getArgs()
+	rt, erh, args, hasExactRoute := r.findRoute(req.Method, reqPath, *argsP)
+	defer r.putArgs(argsP, args)
+
+	if !hasExactRoute {
+		if req.Method == http.MethodOptions {
+			if r.HandleOPTIONS {
+				if allowed := r.allowedMethods(reqPath); len(allowed) > 0 {
+					w.Header().Set("Allow", strings.Join(allowed, ", "))
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+			}
+		} else if r.HandleMethodNotAllowed {
+			if allowed := r.allowedMethods(reqPath); len(allowed) > 0 {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+		}
+	}
 
 	if er := rt(w, req, args); er != nil {
 		erh(w, req, er)
 	}
 }
 
+// allowedMethods returns the upper-cased HTTP methods, sorted, that have an
+// exact route registered for path. Used to build the Allow header for an
+// automatic 405 or OPTIONS response; unlike the hot dispatch path, it isn't
+// pooled, since it's only reached once a request has already missed.
+func (r *Router) allowedMethods(path string) []string {
+	var allowed []string
+
+	for method, sr := range r.children {
+		leaf, _, _, _, _, matched := sr.descendPath(path, nil)
+		if matched && leaf.route != nil {
+			allowed = append(allowed, strings.ToUpper(method))
+		}
+	}
+
+	sort.Strings(allowed)
+
+	return allowed
+}
+
 // Helper function to insert a subrouter entry into the Router's trie.
 func (r *Router) insertSubrouter(method string, url string) (sr *subrouter) {
 	pathParts := strings.Split(url, "/")
@@ -149,75 +449,162 @@ func (r *Router) insertSubrouter(method string, url string) (sr *subrouter) {
 		r.children[method] = sr
 	}
 
+	if id, ok := commonMethodID(strings.ToUpper(method)); ok {
+		r.methodRoots[id] = sr
+	}
+
+	numWildcards := 0
+
 	for _, pathPart := range pathParts {
 		if pathPart == "" {
 			continue
 		}
 
+		if name, pattern, ok := parseRegexSegment(pathPart); ok {
+			sr = sr.insertRegexChild(name, pattern)
+			numWildcards++
+			continue
+		}
+
+		if name, typ, ok := parseTypedSegment(pathPart); ok {
+			sr = sr.insertTypedChild(name, typ)
+			numWildcards++
+			continue
+		}
+
+		if pathPart == "*" {
+			numWildcards++
+		}
+
 		tmp, ok := sr.children[pathPart]
 		if !ok {
 			tmp = newSubrouter()
+			tmp.paramTypeNames = sr.paramTypeNames
+			tmp.capturingParams = sr.capturingParams
+			if pathPart == "*" {
+				tmp.capturingParams++
+			}
 			sr.children[pathPart] = tmp
 		}
 
 		sr = tmp
 	}
 
+	if numWildcards > r.maxArgs {
+		r.maxArgs = numWildcards
+	}
+
 	return sr
 }
 
-// Helper function that walks the Router's trie, gathering wildcard arguments
-// and returning them with the best-matching Route and ErrorHandler.
-func (r *Router) findRoute(method string, pathParts []string) (rt Route, erh ErrorHandler, args []string) {
-	method = strings.ToLower(method)
+// findRoute walks the Router's trie for (method, path) once, appending
+// wildcard arguments to args (typically the slice behind a Router.getArgs
+// pointer, to avoid allocating one per request), and returns the
+// best-matching Route and ErrorHandler along with the resulting args.
+// hasExactRoute reports whether path has a route registered directly for
+// method, as opposed to falling back to a default route -- ServeHTTP uses
+// it to decide whether 405/OPTIONS handling applies.
+func (r *Router) findRoute(method, path string, args []string) (rt Route, erh ErrorHandler, outArgs []string, hasExactRoute bool) {
 	rt = nullRoute
 	erh = nullErrorHandler
+	outArgs = args
 
-	sr, ok := r.children[method]
+	sr, ok := r.rootFor(method)
 	if !ok {
 		return
 	}
 
-	// Set the default/error handlers for the root URL, since the loop won't
-	// be iterated over.
-	if sr.defaultRoute != nil {
-		rt = sr.defaultRoute
+	leaf, leafArgs, mw, defaultRoute, errorHandler, matched := sr.descendPath(path, args)
+	outArgs = leafArgs
+	hasExactRoute = matched && leaf.route != nil
+
+	if defaultRoute != nil {
+		rt = defaultRoute
 	}
 
-	if sr.errorHandler != nil {
-		erh = sr.errorHandler
+	if errorHandler != nil {
+		erh = errorHandler
 	}
 
-	for _, pathPart := range pathParts {
-		if sr.defaultRoute != nil {
-			rt = sr.defaultRoute
+	if leaf.route != nil {
+		rt = leaf.route
+	}
+
+	// Wrap rt in the accumulated middleware stack, outer-most first, so
+	// middleware registered at a shallower prefix wraps middleware (and
+	// routes) registered deeper in the trie.
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+
+	return
+}
+
+// descendPath walks the trie rooted at sr along path, applying the same
+// literal > regex > typed wildcard > untyped "*" precedence used for
+// dispatch. Wildcard values are appended to args (typically obtained from
+// Router.getArgs, to avoid allocating one per request) rather than
+// building a new slice, and path is walked segment-by-segment in place
+// (see nextSegment) rather than via strings.Split.
+//
+// It returns the node reached; the (possibly grown) args slice; the
+// middleware encountered root-to-leaf (for the caller to wrap a route in);
+// the nearest defaultRoute/errorHandler found along the walk (nil if
+// none); and whether every non-empty path part found a matching child --
+// false means the walk stopped early with no match at all, as opposed to
+// reaching a leaf with no route of its own.
+func (sr *subrouter) descendPath(path string, args []string) (leaf *subrouter, outArgs []string, mw []Middleware, defaultRoute Route, errorHandler ErrorHandler, matched bool) {
+	leaf = sr
+	outArgs = args
+	matched = true
+	mw = append(mw, sr.middleware...)
+	defaultRoute = sr.defaultRoute
+	errorHandler = sr.errorHandler
+
+	for from, ok := 0, true; ok; {
+		var pathPart string
+		pathPart, from, ok = nextSegment(path, from)
+		if !ok {
+			break
+		}
+
+		if leaf.defaultRoute != nil {
+			defaultRoute = leaf.defaultRoute
 		}
 
-		if sr.errorHandler != nil {
-			erh = sr.errorHandler
+		if leaf.errorHandler != nil {
+			errorHandler = leaf.errorHandler
 		}
 
-		// Having this here instead of at the beginning of the loop changes the
+		// Having this here instead of at the top of the loop changes the
 		// behavior when the URL has a trailing '/'.
 		if pathPart == "" {
 			continue
 		}
 
-		tmp, ok := sr.children[pathPart]
+		tmp, ok := leaf.children[pathPart]
 		if !ok {
-			tmp, ok = sr.children["*"]
-			if !ok {
-				return
+			// Ambiguity resolution below is literal (above) > regex >
+			// typed wildcard > untyped "*". Regex children are matched in
+			// registration order; typed and untyped wildcards don't
+			// disambiguate between siblings by content, only by position.
+			if rc := leaf.matchRegexChild(pathPart); rc != nil {
+				tmp = rc
+			} else if len(leaf.typedChildren) > 0 {
+				tmp = leaf.typedChildren[0].node
+			} else {
+				tmp, ok = leaf.children["*"]
+				if !ok {
+					matched = false
+					return
+				}
 			}
 
-			args = append(args, pathPart)
+			outArgs = append(outArgs, pathPart)
 		}
 
-		sr = tmp
-	}
-
-	if sr.route != nil {
-		rt = sr.route
+		leaf = tmp
+		mw = append(mw, leaf.middleware...)
 	}
 
 	return
@@ -225,10 +612,31 @@ func (r *Router) findRoute(method string, pathParts []string) (rt Route, erh Err
 
 // Node in the Router's trie.
 type subrouter struct {
-	children map[string]*subrouter
-	route Route
-	defaultRoute Route
-	errorHandler ErrorHandler
+	children      map[string]*subrouter
+	typedChildren []*typedChild
+	regexChildren []*regexChild
+	route         Route
+	defaultRoute  Route
+	errorHandler  ErrorHandler
+	middleware    []Middleware
+
+	// paramTypeNames holds the type name (e.g. "int") of every typed
+	// wildcard from the root of this method's trie down to this node, in
+	// order. It is used to type-check and invoke a handler bound with a
+	// typed signature; see bindTypedHandler.
+	paramTypeNames []string
+
+	// capturingParams counts every wildcard-like segment -- typed, regex,
+	// or untyped "*" -- from the root of this method's trie down to this
+	// node. A typed handler can only be bound here if this equals
+	// len(paramTypeNames), i.e. every capturing segment in the pattern is
+	// one of its typed wildcards; see bindTypedHandler.
+	capturingParams int
+
+	// handler and argPool are only set when this node's route was
+	// registered via a typed handler. See bindTypedHandler in typed.go.
+	handler reflect.Value
+	argPool *sync.Pool
 }
 
 func newSubrouter() *subrouter {
@@ -0,0 +1,21 @@
+package router
+
+import "strings"
+
+// nextSegment returns the next "/"-delimited segment of path starting at
+// byte offset from, and the offset to resume from for the following call.
+// It yields the same sequence of segments as strings.Split(path, "/")
+// (including empty leading/internal/trailing segments), one at a time,
+// without allocating a []string. ok is false once from is past the end of
+// path, i.e. there are no more segments.
+func nextSegment(path string, from int) (seg string, next int, ok bool) {
+	if from > len(path) {
+		return "", 0, false
+	}
+
+	if idx := strings.IndexByte(path[from:], '/'); idx >= 0 {
+		return path[from : from+idx], from + idx + 1, true
+	}
+
+	return path[from:], len(path) + 1, true
+}
@@ -0,0 +1,43 @@
+package router
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNextSegmentMatchesStringsSplit(t *testing.T) {
+	paths := []string{
+		"",
+		"/",
+		"/foo",
+		"/foo/",
+		"/foo/bar",
+		"/foo//bar",
+		"foo/bar",
+		"//",
+	}
+
+	for _, p := range paths {
+		want := strings.Split(p, "/")
+
+		var got []string
+		for from, ok := 0, true; ok; {
+			var seg string
+			seg, from, ok = nextSegment(p, from)
+			if ok {
+				got = append(got, seg)
+			}
+		}
+
+		if len(got) != len(want) {
+			t.Errorf("nextSegment(%q): got %d segments %#v, want %d %#v", p, len(got), got, len(want), want)
+			continue
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("nextSegment(%q): segment %d = %q, want %q", p, i, got[i], want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParamParser converts a raw path segment into a reflect.Value of the Go
+// type registered alongside it (see RegisterParamType). It returns an error
+// if raw cannot be parsed as that type; the error is surfaced to the route's
+// ErrorHandler, same as any other error returned from a Route.
+type ParamParser func(raw string) (reflect.Value, error)
+
+// paramType pairs a ParamParser with the Go type a typed handler argument
+// must declare to receive its result.
+type paramType struct {
+	parser ParamParser
+	goType reflect.Type
+}
+
+var (
+	paramTypesMu sync.RWMutex
+	paramTypes   = map[string]paramType{
+		"string": {parseStringParam, reflect.TypeOf("")},
+		"int":    {parseIntParam, reflect.TypeOf(int64(0))},
+		"uint":   {parseUintParam, reflect.TypeOf(uint64(0))},
+	}
+)
+
+func parseStringParam(raw string) (reflect.Value, error) {
+	return reflect.ValueOf(raw), nil
+}
+
+func parseIntParam(raw string) (reflect.Value, error) {
+	v, er := strconv.ParseInt(raw, 10, 64)
+	if er != nil {
+		return reflect.Value{}, er
+	}
+	return reflect.ValueOf(v), nil
+}
+
+func parseUintParam(raw string) (reflect.Value, error) {
+	v, er := strconv.ParseUint(raw, 10, 64)
+	if er != nil {
+		return reflect.Value{}, er
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// RegisterParamType adds (or overrides) the ParamParser and the Go type used
+// for typed wildcards of the named type, e.g.:
+//
+//     router.RegisterParamType("uuid", parseUUIDParam, reflect.TypeOf(uuid.UUID{}))
+//
+// after which patterns may use ":id:uuid" and a typed handler for that
+// pattern may declare the corresponding argument as uuid.UUID. Built-in
+// types are "string", "int" (int64), and "uint" (uint64).
+//
+// It is not safe to call concurrently with routing.
+func RegisterParamType(name string, parser ParamParser, goType reflect.Type) {
+	paramTypesMu.Lock()
+	defer paramTypesMu.Unlock()
+	paramTypes[name] = paramType{parser, goType}
+}
+
+func lookupParamType(name string) (paramType, bool) {
+	paramTypesMu.RLock()
+	defer paramTypesMu.RUnlock()
+	pt, ok := paramTypes[name]
+	return pt, ok
+}
+
+// typedChild is a trie edge for a typed path parameter, e.g. the ":id:int"
+// segment of "/users/:id:int". A typed child is used after the literal and
+// regex children miss and before the untyped "*" wildcard; only the first
+// typed child registered at a node is ever used, and later typed siblings
+// are not tried.
+type typedChild struct {
+	name string
+	typ  string
+	node *subrouter
+}
+
+// parseTypedSegment reports whether part is a typed wildcard segment of the
+// form ":name:type", returning its param name and type name if so.
+func parseTypedSegment(part string) (name, typ string, ok bool) {
+	if len(part) == 0 || part[0] != ':' {
+		return "", "", false
+	}
+
+	rest := part[1:]
+	idx := strings.IndexByte(rest, ':')
+	if idx < 0 {
+		return "", "", false
+	}
+
+	return rest[:idx], rest[idx+1:], true
+}
+
+// insertTypedChild finds or creates the child node for the named typed
+// wildcard, panicking if typ is not a registered ParamParser type.
+func (sr *subrouter) insertTypedChild(name, typ string) *subrouter {
+	if _, ok := lookupParamType(typ); !ok {
+		panic("router: unknown typed wildcard type " + typ)
+	}
+
+	for _, tc := range sr.typedChildren {
+		if tc.name == name && tc.typ == typ {
+			return tc.node
+		}
+	}
+
+	child := newSubrouter()
+	child.paramTypeNames = append(append([]string{}, sr.paramTypeNames...), typ)
+	child.capturingParams = sr.capturingParams + 1
+
+	sr.typedChildren = append(sr.typedChildren, &typedChild{
+		name: name,
+		typ:  typ,
+		node: child,
+	})
+
+	return child
+}
+
+var (
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	requestType        = reflect.TypeOf((*http.Request)(nil))
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// bindTypedHandler checks handler's signature against sr.paramTypeNames with
+// reflection -- it must be func(http.ResponseWriter, *http.Request, <typed
+// args...>) error, where <typed args...> match the Go types registered for
+// sr.paramTypeNames, in order -- and returns a Route adapter that parses the
+// raw wildcard values and invokes it. It panics on a signature mismatch.
+func (sr *subrouter) bindTypedHandler(handler interface{}) Route {
+	hv := reflect.ValueOf(handler)
+	ht := hv.Type()
+
+	if ht.Kind() != reflect.Func {
+		panic("router: handler is not a func")
+	}
+
+	if sr.capturingParams != len(sr.paramTypeNames) {
+		panic(fmt.Sprintf("router: a typed handler's pattern cannot mix %d untyped/regex wildcard(s) with its %d typed wildcard(s)",
+			sr.capturingParams-len(sr.paramTypeNames), len(sr.paramTypeNames)))
+	}
+
+	wantIn := len(sr.paramTypeNames) + 2
+	if ht.NumIn() != wantIn {
+		panic(fmt.Sprintf("router: handler takes %d args, but the pattern has %d typed wildcards (want %d args)",
+			ht.NumIn(), len(sr.paramTypeNames), wantIn))
+	}
+
+	if ht.In(0) != responseWriterType || ht.In(1) != requestType {
+		panic("router: handler's first two args must be (http.ResponseWriter, *http.Request)")
+	}
+
+	parsers := make([]ParamParser, len(sr.paramTypeNames))
+
+	for i, typ := range sr.paramTypeNames {
+		pt, _ := lookupParamType(typ)
+		if ht.In(i+2) != pt.goType {
+			panic(fmt.Sprintf("router: handler arg %d has type %s, but wildcard %d (type %q) wants %s",
+				i+2, ht.In(i+2), i, typ, pt.goType))
+		}
+		parsers[i] = pt.parser
+	}
+
+	if ht.NumOut() != 1 || ht.Out(0) != errorType {
+		panic("router: handler must return a single error")
+	}
+
+	// Calling a reflect.Value allocates its []reflect.Value argument list;
+	// pool and reuse one sized for this handler so steady-state dispatch
+	// doesn't pay that cost on every request. The pool holds pointers, so
+	// that Put doesn't box a slice header into an interface{} each time.
+	argPool := &sync.Pool{
+		New: func() interface{} {
+			in := make([]reflect.Value, wantIn)
+			return &in
+		},
+	}
+
+	sr.handler = hv
+	sr.argPool = argPool
+
+	return func(w http.ResponseWriter, r *http.Request, args []string) error {
+		if len(args) != len(parsers) {
+			return fmt.Errorf("router: expected %d typed wildcard(s), got %d", len(parsers), len(args))
+		}
+
+		inP := argPool.Get().(*[]reflect.Value)
+		defer argPool.Put(inP)
+		in := *inP
+
+		in[0] = reflect.ValueOf(w)
+		in[1] = reflect.ValueOf(r)
+
+		for i, raw := range args {
+			v, er := parsers[i](raw)
+			if er != nil {
+				return fmt.Errorf("router: parsing %q as typed wildcard %d: %w", raw, i, er)
+			}
+			in[i+2] = v
+		}
+
+		out := hv.Call(in)
+		if er, _ := out[0].Interface().(error); er != nil {
+			return er
+		}
+		return nil
+	}
+}
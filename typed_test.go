@@ -0,0 +1,165 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func testTypedRoute(t *testing.T, rtr *Router, method, urlStr string) (*httptest.ResponseRecorder, error) {
+	u, er := url.Parse(urlStr)
+	if er != nil {
+		t.Fatal(er)
+	}
+
+	w := httptest.NewRecorder()
+
+	var caught error
+	rtr.SetErrorHandler(method, "/", func(w http.ResponseWriter, r *http.Request, er error) {
+		caught = er
+	})
+
+	rtr.ServeHTTP(w, &http.Request{
+		Method: method,
+		URL:    u,
+	})
+
+	return w, caught
+}
+
+func TestTypedRoutes(t *testing.T) {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/users/:id:int/posts/:slug:string",
+		func(w http.ResponseWriter, r *http.Request, id int64, slug string) error {
+			fmt.Fprintf(w, "%d/%s", id, slug)
+			return nil
+		})
+
+	w, er := testTypedRoute(t, rtr, "GET", "/users/42/posts/hello-world")
+	if er != nil {
+		t.Fatalf("unexpected error: %v", er)
+	}
+	if w.Body.String() != "42/hello-world" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestTypedRouteCoercionFailure(t *testing.T) {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/users/:id:int",
+		func(w http.ResponseWriter, r *http.Request, id int64) error {
+			t.Error("handler should not be invoked for an unparseable id")
+			return nil
+		})
+
+	_, er := testTypedRoute(t, rtr, "GET", "/users/not-a-number")
+	if er == nil {
+		t.Fatal("expected a coercion error to reach the ErrorHandler")
+	}
+}
+
+// landingPage is declared exactly like the package doc's example handler --
+// a plain named function with Route's signature, rather than the Route
+// type itself -- to make sure Handle recognizes it structurally instead of
+// only accepting values whose static type already is Route.
+func landingPage(w http.ResponseWriter, r *http.Request, args []string) error {
+	lastVal = 1
+	return nil
+}
+
+func TestHandleAcceptsPlainFuncWithRouteSignature(t *testing.T) {
+	rtr := NewRouter()
+	rtr.Handle("GET", "/", landingPage)
+
+	lastVal = 0
+	testRoute(rtr, "GET", "/")
+	if lastVal != 1 {
+		t.Errorf("expected landingPage to be invoked, got lastVal=%d", lastVal)
+	}
+}
+
+func TestHandleAcceptsBareClosureWithRouteSignature(t *testing.T) {
+	rtr := NewRouter()
+
+	called := false
+	rtr.Handle("GET", "/", func(w http.ResponseWriter, r *http.Request, args []string) error {
+		called = true
+		return nil
+	})
+
+	testRoute(rtr, "GET", "/")
+	if !called {
+		t.Error("expected the bare closure to be invoked")
+	}
+}
+
+func TestTypedHandlerCannotMixUntypedWildcard(t *testing.T) {
+	rtr := NewRouter()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Handle to panic when a typed handler's pattern also has an untyped wildcard")
+		}
+	}()
+
+	rtr.Handle("GET", "/*/users/:id:int",
+		func(w http.ResponseWriter, r *http.Request, id int64) error {
+			return nil
+		})
+}
+
+func TestTypedHandlerCannotMixRegexWildcard(t *testing.T) {
+	rtr := NewRouter()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Handle to panic when a typed handler's pattern also has a regex wildcard")
+		}
+	}()
+
+	rtr.Handle("GET", "/{name:[a-z]+}/users/:id:int",
+		func(w http.ResponseWriter, r *http.Request, id int64) error {
+			return nil
+		})
+}
+
+func TestTypedHandlerSignatureMismatchPanics(t *testing.T) {
+	rtr := NewRouter()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Handle to panic on a signature mismatch")
+		}
+	}()
+
+	rtr.Handle("GET", "/users/:id:int",
+		func(w http.ResponseWriter, r *http.Request, id string) error {
+			return nil
+		})
+}
+
+func TestTypedAndUntypedWildcardsCoexist(t *testing.T) {
+	rtr := NewRouter()
+
+	rtr.Handle("GET", "/*", makeRoute(1))
+	rtr.Handle("GET", "/users/:id:int", func(w http.ResponseWriter, r *http.Request, id int64) error {
+		lastVal = 2
+		return nil
+	})
+
+	testRoute(rtr, "GET", "/anything")
+	if lastVal != 1 {
+		t.Errorf("expected untyped wildcard route, got %d", lastVal)
+	}
+
+	w := httptest.NewRecorder()
+	u, _ := url.Parse("/users/7")
+	rtr.ServeHTTP(w, &http.Request{Method: "GET", URL: u})
+	if lastVal != 2 {
+		t.Errorf("expected typed wildcard route, got %d", lastVal)
+	}
+}